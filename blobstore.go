@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BlobStore persists a rendered image and returns a URL the Slack
+// attachments payload can point at.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) (url string, err error)
+}
+
+// blobStoreFor builds a BlobStore from a DSN-style spec: "file://./out" for
+// a local directory served over HTTP, or "s3://bucket/prefix" for S3.
+func blobStoreFor(spec string) (BlobStore, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: invalid spec %q: %w", spec, err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		return newFileBlobStore(u)
+	case "s3":
+		return newS3BlobStore(u)
+	default:
+		return nil, fmt.Errorf("blobstore: unknown scheme %q", u.Scheme)
+	}
+}
+
+// fileBlobStore writes rendered images to a local directory, which the
+// caller is expected to serve over HTTP at PublicBase (see /out in main.go).
+type fileBlobStore struct {
+	dir        string
+	publicBase string
+}
+
+func newFileBlobStore(u *url.URL) (*fileBlobStore, error) {
+	dir := filepath.Join(u.Host, u.Path)
+	if dir == "" {
+		dir = "."
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("blobstore: failed to create %q: %w", dir, err)
+	}
+
+	publicBase := u.Query().Get("public-base")
+	if publicBase == "" {
+		publicBase = "/out"
+	}
+
+	return &fileBlobStore{dir: dir, publicBase: strings.TrimSuffix(publicBase, "/")}, nil
+}
+
+func (s *fileBlobStore) Put(ctxt context.Context, key string, data []byte) (string, error) {
+	if strings.ContainsAny(key, "/\\") || key == ".." {
+		return "", fmt.Errorf("blobstore: invalid key %q", key)
+	}
+
+	path := filepath.Join(s.dir, key)
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("blobstore: failed to write %q: %w", path, err)
+	}
+
+	return s.publicBase + "/" + key, nil
+}
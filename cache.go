@@ -0,0 +1,142 @@
+package main
+
+import (
+	"container/list"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Cache is a minimal key/value store for memoized meme image URLs, fronting
+// the (slow, metered) calls out to a MemeProvider.
+type Cache interface {
+	Get(key string) (url string, ok bool)
+	Set(key, url string, ttl time.Duration)
+}
+
+// defaultCacheTTL is used whenever a Set call, or the cache's own
+// configuration, doesn't specify one.
+const defaultCacheTTL = 1 * time.Hour
+
+// cacheFor builds a Cache from a DSN-style spec, the same way the badge-gen
+// tool's --cache flag works: "mem://" for an in-process LRU (tunable via
+// ?size= and ?ttl= query params), or "redis://host:port/db" for a
+// Redis-backed cache shared across instances. An empty spec disables
+// caching.
+func cacheFor(spec string) (Cache, error) {
+	if spec == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, fmt.Errorf("cache: invalid spec %q: %w", spec, err)
+	}
+
+	switch u.Scheme {
+	case "mem":
+		return newMemCache(memCacheOptionsFromQuery(u.Query())), nil
+	case "redis":
+		return newRedisCache(u)
+	default:
+		return nil, fmt.Errorf("cache: unknown scheme %q", u.Scheme)
+	}
+}
+
+type memCacheOptions struct {
+	size int
+	ttl  time.Duration
+}
+
+func memCacheOptionsFromQuery(q url.Values) memCacheOptions {
+	opts := memCacheOptions{size: 1000, ttl: defaultCacheTTL}
+
+	if v := q.Get("size"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			opts.size = n
+		}
+	}
+
+	if v := q.Get("ttl"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			opts.ttl = d
+		}
+	}
+
+	return opts
+}
+
+// memCache is an in-process LRU cache with per-entry TTLs.
+type memCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type memCacheEntry struct {
+	key       string
+	url       string
+	expiresAt time.Time
+}
+
+func newMemCache(opts memCacheOptions) *memCache {
+	return &memCache{
+		size:  opts.size,
+		ttl:   opts.ttl,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *memCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+
+	entry := el.Value.(*memCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.removeElement(el)
+		return "", false
+	}
+
+	c.ll.MoveToFront(el)
+	return entry.url, true
+}
+
+func (c *memCache) Set(key, url string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*memCacheEntry)
+		entry.url = url
+		entry.expiresAt = expiresAt
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	el := c.ll.PushFront(&memCacheEntry{key: key, url: url, expiresAt: expiresAt})
+	c.items[key] = el
+
+	if c.size > 0 && c.ll.Len() > c.size {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+func (c *memCache) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*memCacheEntry).key)
+}
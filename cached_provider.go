@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"strings"
+)
+
+// cachedProvider wraps a MemeProvider with a Cache, short-circuiting the
+// outbound call to the underlying provider on a cache hit and populating
+// the cache after a successful miss.
+type cachedProvider struct {
+	providerID string
+	provider   MemeProvider
+	cache      Cache
+	metrics    *metrics
+}
+
+// withCache returns provider unchanged if cache is nil, otherwise wraps it
+// in a cachedProvider. providerID identifies provider in cache keys so that
+// switching --provider doesn't serve a stale URL from a different backend.
+//
+// Entries are stored with ttl 0, which tells the Cache to fall back to
+// whatever TTL its own DSN configured (e.g. mem://?ttl= or redis://?ttl=) -
+// the provider layer doesn't second-guess it with its own default.
+func withCache(providerID string, provider MemeProvider, cache Cache, m *metrics) MemeProvider {
+	if cache == nil {
+		return provider
+	}
+	return &cachedProvider{providerID: providerID, provider: provider, cache: cache, metrics: m}
+}
+
+func (p *cachedProvider) Render(ctxt context.Context, templateID string, lines []string) (string, error) {
+	key := cacheKey(p.providerID, templateID, lines)
+
+	if url, ok := p.cache.Get(key); ok {
+		p.metrics.recordCacheHit()
+		return url, nil
+	}
+
+	p.metrics.recordCacheMiss()
+
+	url, err := p.provider.Render(ctxt, templateID, lines)
+	if err != nil {
+		return "", err
+	}
+
+	p.cache.Set(key, url, 0)
+
+	return url, nil
+}
+
+// cacheKey keys entries on (providerID, imageID, text0, text1, ...) so
+// different templates/captions never collide.
+func cacheKey(providerID, templateID string, lines []string) string {
+	return providerID + "|" + templateID + "|" + strings.Join(lines, "|")
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+)
+
+// redactedFormFields lists the form fields stripped from a form-encoded
+// request body before it's dumped - both memegenerator and imgflip send
+// credentials this way (as well as, for memegenerator, as query params).
+var redactedFormFields = []string{"password", "username"}
+
+// debugTransport wraps an http.RoundTripper, logging the outbound provider
+// request and response at debug level when --debug-http is set. The
+// password query parameter, any Authorization header, and credentials in a
+// form-encoded request body are all redacted before logging.
+type debugTransport struct {
+	next http.RoundTripper
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if dump := dumpRedactedRequest(req); dump != nil {
+		slog.Debug("outbound request", "request_id", requestIDFrom(req.Context()), "dump", string(dump))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	if dump, dumpErr := httputil.DumpResponse(resp, true); dumpErr == nil {
+		slog.Debug("outbound response", "request_id", requestIDFrom(req.Context()), "dump", string(redactAuthorizationHeader(dump)))
+	}
+
+	return resp, err
+}
+
+// dumpRedactedRequest dumps req the way httputil.DumpRequestOut would, but
+// against a clone with the password query parameter, Authorization header,
+// and any credentials in a form-encoded body redacted first, so secrets
+// never reach the log.
+func dumpRedactedRequest(req *http.Request) []byte {
+	clone := req.Clone(req.Context())
+
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			clone.Body = body
+		}
+	}
+
+	redactPasswordQueryParam(clone)
+	clone.Header.Del("Authorization")
+
+	if err := redactFormBody(clone); err != nil {
+		// We can't be sure the body doesn't contain credentials, so don't
+		// log it at all rather than risk leaking them.
+		clone.Body = http.NoBody
+		clone.ContentLength = 0
+	}
+
+	dump, err := httputil.DumpRequestOut(clone, true)
+	if err != nil {
+		return nil
+	}
+
+	return redactAuthorizationHeader(dump)
+}
+
+func redactPasswordQueryParam(req *http.Request) {
+	q := req.URL.Query()
+	if q.Get("password") == "" {
+		return
+	}
+	q.Set("password", "REDACTED")
+	req.URL.RawQuery = q.Encode()
+}
+
+// redactFormBody reads req's form-encoded body (imgflip sends its
+// credentials this way) and replaces redactedFormFields with "REDACTED",
+// leaving req otherwise untouched if there's no body to redact.
+func redactFormBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	if !strings.Contains(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+
+	for _, field := range redactedFormFields {
+		if values.Get(field) != "" {
+			values.Set(field, "REDACTED")
+		}
+	}
+
+	redacted := values.Encode()
+	req.Body = io.NopCloser(strings.NewReader(redacted))
+	req.ContentLength = int64(len(redacted))
+
+	return nil
+}
+
+// redactAuthorizationHeader is a belt-and-braces pass over a raw dump,
+// catching any Authorization header that made it through (e.g. on the
+// response side) without having to know the dump's exact structure.
+func redactAuthorizationHeader(dump []byte) []byte {
+	lines := bytes.Split(dump, []byte("\r\n"))
+
+	for i, line := range lines {
+		if len(line) >= len("authorization:") && bytes.EqualFold(line[:len("authorization:")], []byte("authorization:")) {
+			lines[i] = []byte("Authorization: REDACTED")
+		}
+	}
+
+	return bytes.Join(lines, []byte("\r\n"))
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang/freetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// embeddedTemplates ships with a single 500x500 blank canvas (imageID
+// "16191858", matching the old memegenerator default) so the provider is
+// usable out of the box. Real templates should be added with
+// POST /templates - see templates_handler.go.
+//
+//go:embed templates/*.png
+var embeddedTemplates embed.FS
+
+// LocalProvider renders memes in-process against a catalog of templates,
+// so the service doesn't depend on any third-party meme API at all.
+type LocalProvider struct {
+	catalog *templateCatalog
+	blobs   BlobStore
+}
+
+// NewLocalProvider returns a LocalProvider that stores rendered images in
+// blobs and serves templates from the embedded catalog plus any added at
+// runtime via AddTemplate.
+func NewLocalProvider(blobs BlobStore) *LocalProvider {
+	return &LocalProvider{catalog: &templateCatalog{}, blobs: blobs}
+}
+
+// Render implements MemeProvider.
+func (p *LocalProvider) Render(ctxt context.Context, templateID string, lines []string) (string, error) {
+	data, ok := p.catalog.Get(templateID)
+	if !ok {
+		return "", fmt.Errorf("local: unknown template %q", templateID)
+	}
+
+	base, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("local: failed to decode template %q: %w", templateID, err)
+	}
+
+	composited := drawCaptions(base, lines)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, composited); err != nil {
+		return "", fmt.Errorf("local: failed to encode rendered image: %w", err)
+	}
+
+	return p.blobs.Put(ctxt, blobKey(templateID, lines), buf.Bytes())
+}
+
+// blobKey derives a filesystem-safe blob key from templateID and the
+// caption lines. Both come straight from the request (templateID from the
+// URL path, lines from the Slack "text" field), so they're hashed rather
+// than concatenated directly - otherwise a caption like "../../etc/passwd"
+// would let a caller write outside the configured blob directory.
+func blobKey(templateID string, lines []string) string {
+	sum := sha256.Sum256([]byte(templateID + "\x00" + strings.Join(lines, "\x00")))
+	return hex.EncodeToString(sum[:]) + ".png"
+}
+
+// ListTemplates returns the imageIDs available to Render.
+func (p *LocalProvider) ListTemplates() []string {
+	return p.catalog.List()
+}
+
+// AddTemplate adds (or replaces) a template at runtime.
+func (p *LocalProvider) AddTemplate(imageID string, data []byte) {
+	p.catalog.Put(imageID, data)
+}
+
+// templateCatalog resolves templates from the embedded, build-time catalog
+// plus an in-memory overlay of templates added at runtime - embed.FS is
+// read-only, so runtime additions can't be written back into it.
+type templateCatalog struct {
+	mu      sync.RWMutex
+	overlay map[string][]byte
+}
+
+func (c *templateCatalog) Get(imageID string) ([]byte, bool) {
+	c.mu.RLock()
+	data, ok := c.overlay[imageID]
+	c.mu.RUnlock()
+	if ok {
+		return data, true
+	}
+
+	data, err := embeddedTemplates.ReadFile("templates/" + imageID + ".png")
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+func (c *templateCatalog) Put(imageID string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.overlay == nil {
+		c.overlay = make(map[string][]byte)
+	}
+	c.overlay[imageID] = data
+}
+
+func (c *templateCatalog) List() []string {
+	ids := make(map[string]bool)
+
+	entries, err := fs.ReadDir(embeddedTemplates, "templates")
+	if err == nil {
+		for _, e := range entries {
+			ids[strings.TrimSuffix(e.Name(), ".png")] = true
+		}
+	}
+
+	c.mu.RLock()
+	for id := range c.overlay {
+		ids[id] = true
+	}
+	c.mu.RUnlock()
+
+	res := make([]string, 0, len(ids))
+	for id := range ids {
+		res = append(res, id)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// captionMargin is the padding, in pixels, between a caption and the edge of
+// the image it's drawn on.
+const captionMargin = 10
+
+// drawCaptions composites lines[0] (top) and lines[1] (bottom) onto base in
+// the classic Impact meme style. Anything beyond lines[1] is ignored, since
+// the two-caption layout is part of the format.
+func drawCaptions(base image.Image, lines []string) image.Image {
+	bounds := base.Bounds()
+	canvas := image.NewRGBA(bounds)
+	draw.Draw(canvas, bounds, base, bounds.Min, draw.Src)
+
+	if len(lines) > 0 && lines[0] != "" {
+		drawCaption(canvas, lines[0], bounds.Min.Y+captionMargin+captionFontSize(bounds))
+	}
+
+	if len(lines) > 1 && lines[1] != "" {
+		drawCaption(canvas, lines[1], bounds.Max.Y-captionMargin)
+	}
+
+	return canvas
+}
+
+func captionFontSize(bounds image.Rectangle) int {
+	return bounds.Dy() / 10
+}
+
+// drawCaption draws text, upper-cased, with its baseline at y: a white fill
+// over a black outline stamped at each of the 8 surrounding offsets, giving
+// the classic Impact stroke effect without a dedicated outline renderer.
+func drawCaption(canvas *image.RGBA, text string, y int) {
+	fnt, err := freetype.ParseFont(goregular.TTF)
+	if err != nil {
+		return
+	}
+
+	bounds := canvas.Bounds()
+	size := float64(captionFontSize(bounds))
+	text = strings.ToUpper(text)
+
+	ctx := freetype.NewContext()
+	ctx.SetDPI(72)
+	ctx.SetFont(fnt)
+	ctx.SetFontSize(size)
+	ctx.SetClip(bounds)
+	ctx.SetDst(canvas)
+	ctx.SetHinting(font.HintingFull)
+
+	x := bounds.Min.X + captionMargin
+
+	for _, offset := range [][2]int{{-1, -1}, {0, -1}, {1, -1}, {-1, 0}, {1, 0}, {-1, 1}, {0, 1}, {1, 1}} {
+		ctx.SetSrc(image.NewUniform(color.Black))
+		ctx.DrawString(text, freetype.Pt(x+offset[0], y+offset[1]))
+	}
+
+	ctx.SetSrc(image.NewUniform(color.White))
+	ctx.DrawString(text, freetype.Pt(x, y))
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+)
+
+// configureLogging installs a slog default logger honoring --log-level
+// (debug, info, warn, error) and --log-format (text, json), replacing the
+// ad-hoc fmt.Printf/log.Println calls this service used to make.
+func configureLogging(level, format string) {
+	opts := &slog.HandlerOptions{Level: parseLogLevel(level)}
+
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	}
+
+	slog.SetDefault(slog.New(handler))
+}
+
+func parseLogLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
@@ -5,10 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"flag"
-	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"net/url"
 	"os"
 	"os/signal"
 	"strconv"
@@ -47,29 +45,88 @@ type errorResponse struct {
 var ErrBadRequest = errors.New("Bad request - no text provided")
 
 func main() {
+	providerName := flag.String("provider", getDefaultConfig("PROVIDER", "memegenerator"), "meme provider to use (memegenerator, imgflip, local). local ships only a blank placeholder template - POST /templates to add real ones")
+	cacheSpec := flag.String("cache", getDefaultConfig("CACHE", ""), "cache backend to use (mem://, redis://host:port/db), empty disables caching")
+	blobStoreSpec := flag.String("blob-store", getDefaultConfig("BLOB_STORE", "file://./out"), "blob store used by the local provider (file://./out, s3://bucket/prefix)")
+	templateAdminToken := flag.String("template-admin-token", getDefaultConfig("TEMPLATE_ADMIN_TOKEN", ""), "admin token required to POST /templates; empty disables the endpoint")
+	logLevel := flag.String("log-level", getDefaultConfig("LOG_LEVEL", "info"), "log level (debug, info, warn, error)")
+	logFormat := flag.String("log-format", getDefaultConfig("LOG_FORMAT", "text"), "log format (text, json)")
+	debugHTTP := flag.Bool("debug-http", getDefaultConfig("DEBUG_HTTP", "") != "", "dump outbound provider requests/responses at debug level, with secrets redacted")
 	flag.Parse()
 
+	configureLogging(*logLevel, *logFormat)
+
 	port := getDefaultConfig("PORT", "8080")
 	username := getDefaultConfig("MG_USERNAME", "")
 	password := getDefaultConfig("MG_PASSWORD", "")
 
-	if username == "" || password == "" {
-		fmt.Printf("No username or password supplied in the environment variables")
+	if *providerName != "local" && (username == "" || password == "") {
+		slog.Error("no username or password supplied in the environment variables")
 		os.Exit(1)
 	}
 
 	client := &http.Client{Timeout: 10 * time.Second}
+	if *debugHTTP {
+		client.Transport = &debugTransport{next: http.DefaultTransport}
+	}
+
+	var blobs BlobStore
+	if *providerName == "local" {
+		var err error
+		blobs, err = blobStoreFor(*blobStoreSpec)
+		if err != nil {
+			slog.Error("failed to configure blob store", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	baseProvider, err := providerFor(*providerName, username, password, client, blobs)
+	if err != nil {
+		slog.Error("failed to configure meme provider", "error", err)
+		os.Exit(1)
+	}
+
+	cache, err := cacheFor(*cacheSpec)
+	if err != nil {
+		slog.Error("failed to configure cache", "error", err)
+		os.Exit(1)
+	}
+
+	stats := &metrics{}
+	provider := withCache(*providerName, baseProvider, cache, stats)
+
+	handler := mainHandlerFor(provider)
+
+	signingSecret := getDefaultConfig("SLACK_SIGNING_SECRET", "")
+	if signingSecret == "" {
+		slog.Warn("SLACK_SIGNING_SECRET is not set - accepting unsigned requests. Do not run this in production.")
+	} else {
+		handler = verifySlackSignature(signingSecret, handler)
+	}
 
 	serveMux := http.NewServeMux()
 
-	serveMux.HandleFunc("/", mainHandlerFor(username, password, client))
+	// "/" does path-based imageID dispatch (see parseImageID), so "metrics"
+	// and "templates" are reserved: a Slack slash command pointed at
+	// POST /metrics or POST /templates hits these exact-match routes
+	// instead of mainHandlerFor, rather than rendering a meme for an
+	// imageID of that name.
+	serveMux.HandleFunc("/", handler)
+	serveMux.Handle("/metrics", stats)
+
+	if local, ok := baseProvider.(*LocalProvider); ok {
+		serveMux.HandleFunc("/templates", templatesHandlerFor(local, *templateAdminToken))
+	}
+	if fileBlobs, ok := blobs.(*fileBlobStore); ok {
+		serveMux.Handle(fileBlobs.publicBase+"/", http.StripPrefix(fileBlobs.publicBase+"/", http.FileServer(http.Dir(fileBlobs.dir))))
+	}
 
 	srv := &http.Server{
 		Addr:         ":" + port,
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
-		Handler:      serveMux,
+		Handler:      loggingMiddleware(serveMux),
 	}
 
 	errorChan := make(chan error, 1)
@@ -85,10 +142,11 @@ func main() {
 		select {
 		case err := <-errorChan:
 			if err != nil {
-				log.Fatal(err)
+				slog.Error("server error", "error", err)
+				os.Exit(1)
 			}
 		case s := <-signalChan:
-			log.Println(fmt.Sprintf("Captured %v. Exiting ...", s))
+			slog.Info("captured signal, exiting", "signal", s)
 			d := time.Now().Add(1 * time.Second)
 			ctx, cancel := context.WithDeadline(context.Background(), d)
 			defer cancel()
@@ -106,9 +164,9 @@ func getDefaultConfig(name, fallback string) string {
 }
 
 // Handles `POST /` and `POST /imageID[/]`
-func mainHandlerFor(username, password string, client *http.Client) http.HandlerFunc {
+func mainHandlerFor(provider MemeProvider) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctxt, cancel := context.WithCancel(context.Background())
+		ctxt, cancel := context.WithCancel(r.Context())
 		defer cancel()
 
 		err := r.ParseForm()
@@ -126,16 +184,9 @@ func mainHandlerFor(username, password string, client *http.Client) http.Handler
 			return
 		}
 
-		args := strings.Split(text, ":")
-
-		text0 := args[0]
-		text1 := ""
+		lines := strings.Split(text, ":")
 
-		if len(args) > 1 {
-			text1 = args[1]
-		}
-
-		imageURL, err := getImageURL(ctxt, client, username, password, imageID, text0, text1)
+		imageURL, err := provider.Render(ctxt, imageID, lines)
 
 		if err != nil {
 			renderError(w, 500, err)
@@ -197,58 +248,13 @@ func filter(parts []string, fn func(string) bool) []string {
 	return res
 }
 
-func getImageURL(ctxt context.Context, client *http.Client, username, password, imageID, text0, text1 string) (*string, error) {
-	u, err := url.Parse("http://version1.api.memegenerator.net/Instance_Create")
-	if err != nil {
-		return nil, err
-	}
-
-	v := url.Values{}
-	v.Add("username", username)
-	v.Add("password", password)
-	v.Add("languageCode", "en")
-	v.Add("text0", text0)
-	v.Add("text1", text1)
-	v.Add("imageID", imageID)
-	v.Add("generatorID", "6693723")
-
-	u.RawQuery = v.Encode()
-
-	resp, err := client.Get(u.String())
-
-	if err != nil {
-		fmt.Printf("Failed to get successful response back\n")
-		return nil, err
-	}
-
-	var doc interface{}
-	defer resp.Body.Close()
-
-	err = json.NewDecoder(resp.Body).Decode(&doc)
-
-	if err != nil {
-		fmt.Printf("Failed to deserialise response body: %v\n", err)
-		return nil, err
-	}
-
-	if m, ok := doc.(map[string]interface{}); ok == true {
-		if res, ok := m["result"].(map[string]interface{}); ok == true {
-			if URL, ok := res["instanceImageUrl"].(string); ok == true {
-				return &URL, nil
-			}
-		}
-	}
-
-	return nil, fmt.Errorf("Unable to parse response JSON - %#v", doc)
-}
-
-func newPayload(imageURL *string) *payload {
+func newPayload(imageURL string) *payload {
 	return &payload{
 		ResponseType: "in_channel",
 		Attachments: []attachment{
 			{
-				Text:     *imageURL,
-				ImageURL: *imageURL,
+				Text:     imageURL,
+				ImageURL: imageURL,
 			},
 		},
 	}
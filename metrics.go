@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// metrics holds process-wide counters exposed at /metrics in Prometheus
+// text exposition format.
+type metrics struct {
+	cacheHits   int64
+	cacheMisses int64
+}
+
+func (m *metrics) recordCacheHit() {
+	atomic.AddInt64(&m.cacheHits, 1)
+}
+
+func (m *metrics) recordCacheMiss() {
+	atomic.AddInt64(&m.cacheMisses, 1)
+}
+
+// ServeHTTP implements http.Handler so metrics can be mounted straight onto
+// the serveMux at /metrics.
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	fmt.Fprintln(w, "# HELP meme_cache_hits_total Number of meme provider cache hits.")
+	fmt.Fprintln(w, "# TYPE meme_cache_hits_total counter")
+	fmt.Fprintf(w, "meme_cache_hits_total %d\n", atomic.LoadInt64(&m.cacheHits))
+
+	fmt.Fprintln(w, "# HELP meme_cache_misses_total Number of meme provider cache misses.")
+	fmt.Fprintln(w, "# TYPE meme_cache_misses_total counter")
+	fmt.Fprintf(w, "meme_cache_misses_total %d\n", atomic.LoadInt64(&m.cacheMisses))
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MemeProvider renders a meme for the given template using the supplied
+// caption lines and returns the URL of the generated image. Implementations
+// are free to support as many lines as their backend allows; callers should
+// not assume a fixed number of captions.
+type MemeProvider interface {
+	Render(ctx context.Context, templateID string, lines []string) (string, error)
+}
+
+// providerFor builds the MemeProvider selected by name, wiring in the shared
+// credentials, HTTP client and (for the local provider) blob store. It
+// returns an error for unrecognised names so misconfiguration fails fast at
+// startup rather than at request time.
+func providerFor(name, username, password string, client *http.Client, blobs BlobStore) (MemeProvider, error) {
+	switch name {
+	case "", "memegenerator":
+		return &MemeGeneratorProvider{Username: username, Password: password, Client: client}, nil
+	case "imgflip":
+		return &ImgFlipProvider{Username: username, Password: password, Client: client}, nil
+	case "local":
+		if blobs == nil {
+			return nil, fmt.Errorf("provider %q requires --blob-store to be set", name)
+		}
+		return NewLocalProvider(blobs), nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+}
+
+// MemeGeneratorProvider renders memes via the long-defunct memegenerator.net
+// API. It is kept around for backward compatibility; new deployments should
+// prefer ImgFlipProvider.
+type MemeGeneratorProvider struct {
+	Username string
+	Password string
+	Client   *http.Client
+}
+
+// Render implements MemeProvider. memegenerator only understands a top and
+// bottom line, so anything beyond lines[1] is silently ignored, matching the
+// service's historical behavior.
+func (p *MemeGeneratorProvider) Render(ctxt context.Context, templateID string, lines []string) (string, error) {
+	text0, text1 := lineAt(lines, 0), lineAt(lines, 1)
+
+	u, err := url.Parse("http://version1.api.memegenerator.net/Instance_Create")
+	if err != nil {
+		return "", err
+	}
+
+	v := url.Values{}
+	v.Add("username", p.Username)
+	v.Add("password", p.Password)
+	v.Add("languageCode", "en")
+	v.Add("text0", text0)
+	v.Add("text1", text1)
+	v.Add("imageID", templateID)
+	v.Add("generatorID", "6693723")
+
+	u.RawQuery = v.Encode()
+
+	req, err := http.NewRequestWithContext(ctxt, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("memegenerator: failed to get successful response back: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var doc interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return "", fmt.Errorf("memegenerator: failed to deserialise response body: %w", err)
+	}
+
+	if m, ok := doc.(map[string]interface{}); ok {
+		if res, ok := m["result"].(map[string]interface{}); ok {
+			if URL, ok := res["instanceImageUrl"].(string); ok {
+				return URL, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("memegenerator: unable to parse response JSON - %#v", doc)
+}
+
+// ImgFlipProvider renders memes via the imgflip.com caption_image API, which
+// supports an arbitrary number of caption lines (text0, text1, ... textN).
+type ImgFlipProvider struct {
+	Username string
+	Password string
+	Client   *http.Client
+}
+
+type imgFlipResponse struct {
+	Success  bool   `json:"success"`
+	ErrorMsg string `json:"error_message"`
+	Data     struct {
+		URL string `json:"url"`
+	} `json:"data"`
+}
+
+// Render implements MemeProvider.
+func (p *ImgFlipProvider) Render(ctxt context.Context, templateID string, lines []string) (string, error) {
+	v := url.Values{}
+	v.Add("template_id", templateID)
+	v.Add("username", p.Username)
+	v.Add("password", p.Password)
+
+	for i, line := range lines {
+		v.Add("text"+strconv.Itoa(i), line)
+	}
+
+	req, err := http.NewRequestWithContext(ctxt, http.MethodPost, "https://api.imgflip.com/caption_image", strings.NewReader(v.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("imgflip: failed to get successful response back: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var doc imgFlipResponse
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return "", fmt.Errorf("imgflip: failed to deserialise response body: %w", err)
+	}
+
+	if !doc.Success {
+		return "", fmt.Errorf("imgflip: %s", doc.ErrorMsg)
+	}
+
+	return doc.Data.URL, nil
+}
+
+// lineAt returns the line at index i, or "" if lines is too short. It lets
+// providers that only support a fixed number of captions accept a shorter
+// slice without bounds-checking at every call site.
+func lineAt(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
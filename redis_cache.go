@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// redisCache is a Cache backed by a shared Redis instance, for deployments
+// running more than one replica of this service.
+type redisCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// newRedisCache builds a redisCache from a "redis://host:port/db" URL. The
+// db path segment is optional and defaults to 0; a "?ttl=" query param
+// overrides defaultCacheTTL.
+func newRedisCache(u *url.URL) (*redisCache, error) {
+	db := 0
+
+	if path := strings.TrimPrefix(u.Path, "/"); path != "" {
+		n, err := strconv.Atoi(path)
+		if err != nil {
+			return nil, fmt.Errorf("cache: invalid redis db %q: %w", path, err)
+		}
+		db = n
+	}
+
+	ttl := defaultCacheTTL
+	if v := u.Query().Get("ttl"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			ttl = d
+		}
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr: u.Host,
+		DB:   db,
+	})
+
+	return &redisCache{client: client, ttl: ttl}, nil
+}
+
+func (c *redisCache) Get(key string) (string, bool) {
+	val, err := c.client.Get(context.Background(), key).Result()
+	if err != nil {
+		return "", false
+	}
+	return val, true
+}
+
+func (c *redisCache) Set(key, url string, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+	c.client.Set(context.Background(), key, url, ttl)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3BlobStore writes rendered images to S3 and returns their public,
+// virtual-hosted-style URL.
+type s3BlobStore struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+func newS3BlobStore(u *url.URL) (*s3BlobStore, error) {
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("blobstore: failed to load AWS config: %w", err)
+	}
+
+	return &s3BlobStore{
+		client: s3.NewFromConfig(cfg),
+		bucket: u.Host,
+		prefix: strings.Trim(u.Path, "/"),
+	}, nil
+}
+
+func (s *s3BlobStore) Put(ctxt context.Context, key string, data []byte) (string, error) {
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+
+	_, err := s.client.PutObject(ctxt, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("image/png"),
+		ACL:         types.ObjectCannedACLPublicRead,
+	})
+	if err != nil {
+		return "", fmt.Errorf("blobstore: failed to upload %q: %w", key, err)
+	}
+
+	return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", s.bucket, key), nil
+}
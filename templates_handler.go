@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+)
+
+// ErrUnauthorized is returned when a /templates admin request is missing or
+// has an invalid admin token.
+var ErrUnauthorized = errors.New("Unauthorized")
+
+// maxTemplateUploadSize bounds the multipart body accepted by
+// templatesHandlerFor's POST handler.
+const maxTemplateUploadSize = 10 << 20 // 10MB
+
+// templatesHandlerFor serves GET /templates (list available imageIDs) and
+// POST /templates (admin-token gated, multipart upload of a new template).
+func templatesHandlerFor(provider *LocalProvider, adminToken string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			listTemplates(w, provider)
+		case http.MethodPost:
+			addTemplate(w, r, provider, adminToken)
+		default:
+			renderError(w, 405, ErrBadRequest)
+		}
+	}
+}
+
+func listTemplates(w http.ResponseWriter, provider *LocalProvider) {
+	writeStatus(w, 200)
+	writeJSON(w, struct {
+		ImageIDs []string `json:"imageIds"`
+	}{ImageIDs: provider.ListTemplates()})
+}
+
+func addTemplate(w http.ResponseWriter, r *http.Request, provider *LocalProvider, adminToken string) {
+	if adminToken == "" || r.Header.Get("X-Admin-Token") != adminToken {
+		renderError(w, 401, ErrUnauthorized)
+		return
+	}
+
+	imageID := r.FormValue("imageId")
+	if imageID == "" {
+		renderError(w, 400, ErrBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("template")
+	if err != nil {
+		renderError(w, 400, ErrBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxTemplateUploadSize))
+	if err != nil {
+		renderError(w, 400, err)
+		return
+	}
+
+	provider.AddTemplate(imageID, data)
+
+	writeStatus(w, 201)
+	writeJSON(w, struct {
+		ImageID string `json:"imageId"`
+	}{ImageID: imageID})
+}